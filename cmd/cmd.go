@@ -114,6 +114,18 @@ func newRootCommand() *cobra.Command {
 	rootFlags.StringVar(&runtimeCfg.WebListenIP, "web-ip", "127.0.0.1", "Address to serve frontend page on")
 	rootFlags.IntVar(&runtimeCfg.WebListenPort, "web-port", 3579, "Port to serve frontend page on")
 	rootFlags.IntVar(&runtimeCfg.ModelUpdateFrequency, "update-frequency", 60, "Times per second the live VRM model data is sent to each client")
+	rootFlags.BoolVar(&runtimeCfg.MetricsEnabled, "metrics-enabled", false, "Enable the Prometheus /metrics endpoint")
+	rootFlags.StringVar(&runtimeCfg.MetricsListenAddr, "metrics-listen-addr", "127.0.0.1:9090", "Address to serve Prometheus metrics on, if enabled")
+	rootFlags.StringVar(&runtimeCfg.OIDCIssuer, "oidc-issuer", "", "OIDC issuer URL used to protect the admin API. Leave empty to disable auth")
+	rootFlags.StringVar(&runtimeCfg.OIDCClientID, "oidc-client-id", "", "OIDC client ID (audience) expected in bearer tokens")
+	rootFlags.StringVar(&runtimeCfg.OIDCRequiredClaim, "oidc-required-claim", "", "Require a \"key=value\" claim in bearer tokens, e.g. \"role=admin\"")
+	rootFlags.StringVar(&runtimeCfg.ZMQSubAddr, "zmq-sub", "", "Address of a ZeroMQ PUB socket to subscribe to for motion data, e.g. tcp://127.0.0.1:5556")
+	rootFlags.StringVar(&runtimeCfg.ZMQPubAddr, "zmq-pub", "", "Address to bind a ZeroMQ PUB socket on, mirroring the VRM state of the receiver active at startup. Disabled if empty")
+	rootFlags.IntVar(&runtimeCfg.ZMQPubRate, "zmq-rate", 60, "Times per second the ZeroMQ publisher mirrors VRM state, if --zmq-pub is set")
+	rootFlags.StringVar(&runtimeCfg.GRPCListenIP, "grpc-ip", "127.0.0.1", "Address to serve the gRPC API on")
+	rootFlags.IntVar(&runtimeCfg.GRPCListenPort, "grpc-port", 3580, "Port to serve the gRPC API on")
+
+	rootCmd.AddCommand(newReplayCommand())
 
 	return rootCmd
 
@@ -0,0 +1,77 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thatpix3l/fntwo/pkg/receivers/replay"
+)
+
+// newReplayCommand returns the "replay" subcommand, for inspecting and
+// trimming recordings made by pkg/receivers/replay offline, without
+// starting the full server.
+func newReplayCommand() *cobra.Command {
+
+	replayCmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Inspect and trim recordings made with the replay receiver",
+	}
+
+	var trimStart, trimEnd float64
+
+	inspectCmd := &cobra.Command{
+		Use:   "inspect <recording>",
+		Short: "Print summary information about a recording",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			summary, err := replay.Inspect(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Duration: %s\n", summary.Duration)
+			fmt.Printf("Frames:   %d\n", summary.FrameCount)
+			fmt.Printf("Keys:     %d\n", len(summary.Keys))
+			for _, key := range summary.Keys {
+				fmt.Printf("  - %s\n", key)
+			}
+
+			return nil
+
+		},
+	}
+
+	trimCmd := &cobra.Command{
+		Use:   "trim <recording> <output>",
+		Short: "Write a new recording containing only the given time range",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return replay.Trim(args[0], args[1], trimStart, trimEnd)
+		},
+	}
+	trimCmd.Flags().Float64Var(&trimStart, "start", 0, "Start of the range to keep, in seconds")
+	trimCmd.Flags().Float64Var(&trimEnd, "end", 0, "End of the range to keep, in seconds. 0 means the end of the recording")
+
+	replayCmd.AddCommand(inspectCmd, trimCmd)
+
+	return replayCmd
+
+}
@@ -0,0 +1,165 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package auth optionally gates fntwo's mutating HTTP routes behind an
+// OIDC bearer token. When no issuer is configured, RequireIDToken is a
+// no-op, so single-user local setups keep working without any of this.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// claimsContextKey is the context key verified ID token claims are stored
+// under, so downstream handlers can read who made the request.
+type claimsContextKey struct{}
+
+// Verifier validates bearer tokens against a single OIDC provider.
+// It is built once at startup from cfg.Keys and reused for every request;
+// the underlying oidc.IDTokenVerifier refreshes its JWKS on its own.
+type Verifier struct {
+	enabled       bool
+	idTokenVerify *oidc.IDTokenVerifier
+	requiredClaim string
+}
+
+// New builds a Verifier from an OIDC issuer URL, expected client ID (audience),
+// and an optional "key=value" claim that every token must carry, e.g. "role=admin".
+// If issuer is empty, the returned Verifier is disabled and RequireIDToken becomes a no-op,
+// so fntwo keeps working unauthenticated for local, single-user setups.
+func New(ctx context.Context, issuer, clientID, requiredClaim string) (*Verifier, error) {
+
+	if issuer == "" {
+		return &Verifier{enabled: false}, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovering OIDC provider %q: %w", issuer, err)
+	}
+
+	return &Verifier{
+		enabled:       true,
+		idTokenVerify: provider.Verifier(&oidc.Config{ClientID: clientID}),
+		requiredClaim: requiredClaim,
+	}, nil
+
+}
+
+// bearerToken pulls the token out of a standard "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, error) {
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", fmt.Errorf("missing Authorization header")
+	}
+
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return "", fmt.Errorf("Authorization header is not a bearer token")
+	}
+
+	return strings.TrimPrefix(header, prefix), nil
+
+}
+
+// hasRequiredClaim checks a "key=value" requirement, e.g. "role=admin", against decoded claims.
+// An empty requirement always passes.
+func hasRequiredClaim(claims map[string]interface{}, requirement string) bool {
+
+	if requirement == "" {
+		return true
+	}
+
+	parts := strings.SplitN(requirement, "=", 2)
+	if len(parts) != 2 {
+		log.Printf("Ignoring malformed required claim %q, expected \"key=value\"", requirement)
+		return true
+	}
+
+	value, ok := claims[parts[0]]
+	if !ok {
+		return false
+	}
+
+	return fmt.Sprintf("%v", value) == parts[1]
+
+}
+
+// RequireIDToken wraps next so it only runs after a valid OIDC ID token is
+// presented. Verified claims are injected into the request context. When
+// the Verifier was built with no issuer, this is a no-op passthrough.
+func (v *Verifier) RequireIDToken(next http.Handler) http.Handler {
+
+	if !v.enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Browsers never attach an Authorization header to a CORS preflight
+		// request, so gating OPTIONS behind the same check as the real
+		// PUT/POST would fail every preflight once an issuer is configured.
+		// The actual mutating request is still verified normally.
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rawToken, err := bearerToken(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		idToken, err := v.idTokenVerify.Verify(r.Context(), rawToken)
+		if err != nil {
+			log.Printf("Rejected bearer token: %s", err)
+			http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		var claims map[string]interface{}
+		if err := idToken.Claims(&claims); err != nil {
+			log.Printf("Failed to decode ID token claims: %s", err)
+			http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		if !hasRequiredClaim(claims, v.requiredClaim) {
+			http.Error(w, "token missing required claim", http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), claimsContextKey{}, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+	})
+
+}
+
+// ClaimsFromContext returns the verified claims stored by RequireIDToken, if any.
+func ClaimsFromContext(ctx context.Context) (map[string]interface{}, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(map[string]interface{})
+	return claims, ok
+}
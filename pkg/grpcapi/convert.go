@@ -0,0 +1,100 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package grpcapi
+
+import (
+	"fmt"
+
+	"github.com/thatpix3l/fntwo/obj"
+	"github.com/thatpix3l/fntwo/pkg/grpcapi/fntwopb"
+)
+
+// toProtoQuaternion converts obj.QuaternionRotation to its proto equivalent.
+func toProtoQuaternion(q obj.QuaternionRotation) *fntwopb.QuaternionRotation {
+	return &fntwopb.QuaternionRotation{X: q.X, Y: q.Y, Z: q.Z, W: q.W}
+}
+
+// fromProtoQuaternion converts a proto QuaternionRotation back to
+// obj.QuaternionRotation. q is a pointer because it's an optional
+// sub-message on the wire; unlike obj.QuaternionRotation, a client can
+// simply omit it, so callers must check for nil before relying on it.
+func fromProtoQuaternion(q *fntwopb.QuaternionRotation) (obj.QuaternionRotation, error) {
+	if q == nil {
+		return obj.QuaternionRotation{}, fmt.Errorf("missing quaternion")
+	}
+	return obj.QuaternionRotation{X: q.X, Y: q.Y, Z: q.Z, W: q.W}, nil
+}
+
+// toProtoVRM converts the full live obj.VRM state into its proto equivalent.
+func toProtoVRM(vrm *obj.VRM) *fntwopb.VRM {
+
+	protoVRM := &fntwopb.VRM{
+		Bones:       make(map[string]*fntwopb.Bone, len(vrm.Bones)),
+		BlendShapes: make(map[string]*fntwopb.BlendShape, len(vrm.BlendShapes)),
+	}
+
+	for key, bone := range vrm.Bones {
+		protoVRM.Bones[key] = &fntwopb.Bone{
+			Rotation: &fntwopb.Rotation{
+				Quaternion: toProtoQuaternion(bone.Rotation.Quaternion),
+			},
+		}
+	}
+
+	for key, blendShape := range vrm.BlendShapes {
+		protoVRM.BlendShapes[key] = &fntwopb.BlendShape{Value: float64(blendShape)}
+	}
+
+	return protoVRM
+
+}
+
+// toProtoCamera converts obj.Camera to its proto equivalent.
+func toProtoCamera(camera *obj.Camera) *fntwopb.Camera {
+	return &fntwopb.Camera{
+		Fov:      camera.Fov,
+		Rotation: toProtoQuaternion(camera.Rotation.Quaternion),
+		Position: &fntwopb.Position{X: camera.Position.X, Y: camera.Position.Y, Z: camera.Position.Z},
+	}
+}
+
+// fromProtoCamera converts a proto Camera back to obj.Camera. Rotation and
+// Position are both pointers on the wire, so a client is free to send a
+// Camera with either left unset; return an error instead of indexing
+// through a nil pointer in that case.
+func fromProtoCamera(camera *fntwopb.Camera) (obj.Camera, error) {
+
+	if camera.Rotation == nil {
+		return obj.Camera{}, fmt.Errorf("camera missing rotation")
+	}
+	if camera.Position == nil {
+		return obj.Camera{}, fmt.Errorf("camera missing position")
+	}
+
+	quaternion, err := fromProtoQuaternion(camera.Rotation)
+	if err != nil {
+		return obj.Camera{}, err
+	}
+
+	return obj.Camera{
+		Fov:      camera.Fov,
+		Rotation: obj.Rotation{Quaternion: quaternion},
+		Position: obj.Position{X: camera.Position.X, Y: camera.Position.Y, Z: camera.Position.Z},
+	}, nil
+
+}
@@ -0,0 +1,63 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fntwopb
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is this codec's content-subtype. It is registered under its
+// own name rather than "proto", grpc-go's default codec name, so it never
+// shadows the real protobuf codec process-wide - anything else in this
+// binary that dials a genuine protobuf service keeps working unaffected.
+const codecName = "json"
+
+// jsonCodec puts our hand-written message types on the wire as JSON
+// instead of the usual protobuf binary format, since there's no protoc
+// step generating real protobuf marshaling for them. A real client built
+// from fntwo.proto via protoc expects actual protobuf and cannot talk to
+// this service; JSONCallOption is how a client written against this
+// package opts into this codec explicitly instead.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// JSONCallOption selects this package's JSON codec for a single gRPC call,
+// the way a client dialing FntwoService must, since grpc-go otherwise
+// defaults to the real protobuf codec, which jsonCodec's types don't
+// implement.
+func JSONCallOption() grpc.CallOption {
+	return grpc.CallContentSubtype(codecName)
+}
@@ -0,0 +1,165 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fntwopb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FntwoServiceServer is the server API for the FntwoService described by
+// ../fntwo.proto.
+type FntwoServiceServer interface {
+	StreamModel(*StreamModelRequest, FntwoService_StreamModelServer) error
+	SyncCamera(FntwoService_SyncCameraServer) error
+	SetReceiver(context.Context, *SetReceiverRequest) (*SetReceiverResponse, error)
+	GetAppConfig(context.Context, *GetAppConfigRequest) (*AppConfig, error)
+}
+
+// UnimplementedFntwoServiceServer can be embedded in a server
+// implementation to satisfy FntwoServiceServer without defining every
+// method, the same way protoc-gen-go-grpc's forward-compatibility stub works.
+type UnimplementedFntwoServiceServer struct{}
+
+func (UnimplementedFntwoServiceServer) StreamModel(*StreamModelRequest, FntwoService_StreamModelServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamModel not implemented")
+}
+
+func (UnimplementedFntwoServiceServer) SyncCamera(FntwoService_SyncCameraServer) error {
+	return status.Errorf(codes.Unimplemented, "method SyncCamera not implemented")
+}
+
+func (UnimplementedFntwoServiceServer) SetReceiver(context.Context, *SetReceiverRequest) (*SetReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetReceiver not implemented")
+}
+
+func (UnimplementedFntwoServiceServer) GetAppConfig(context.Context, *GetAppConfigRequest) (*AppConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAppConfig not implemented")
+}
+
+// FntwoService_StreamModelServer is the server-side stream for StreamModel.
+type FntwoService_StreamModelServer interface {
+	Send(*VRM) error
+	grpc.ServerStream
+}
+
+type fntwoServiceStreamModelServer struct {
+	grpc.ServerStream
+}
+
+func (x *fntwoServiceStreamModelServer) Send(m *VRM) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// FntwoService_SyncCameraServer is the server-side stream for SyncCamera.
+type FntwoService_SyncCameraServer interface {
+	Send(*Camera) error
+	Recv() (*Camera, error)
+	grpc.ServerStream
+}
+
+type fntwoServiceSyncCameraServer struct {
+	grpc.ServerStream
+}
+
+func (x *fntwoServiceSyncCameraServer) Send(m *Camera) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *fntwoServiceSyncCameraServer) Recv() (*Camera, error) {
+	m := new(Camera)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _FntwoService_StreamModel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamModelRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FntwoServiceServer).StreamModel(m, &fntwoServiceStreamModelServer{stream})
+}
+
+func _FntwoService_SyncCamera_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FntwoServiceServer).SyncCamera(&fntwoServiceSyncCameraServer{stream})
+}
+
+func _FntwoService_SetReceiver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+
+	in := new(SetReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FntwoServiceServer).SetReceiver(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/fntwo.FntwoService/SetReceiver"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FntwoServiceServer).SetReceiver(ctx, req.(*SetReceiverRequest))
+	}
+
+	return interceptor(ctx, in, info, handler)
+
+}
+
+func _FntwoService_GetAppConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+
+	in := new(GetAppConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FntwoServiceServer).GetAppConfig(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/fntwo.FntwoService/GetAppConfig"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FntwoServiceServer).GetAppConfig(ctx, req.(*GetAppConfigRequest))
+	}
+
+	return interceptor(ctx, in, info, handler)
+
+}
+
+// FntwoService_ServiceDesc mirrors the grpc.ServiceDesc protoc-gen-go-grpc
+// would generate from ../fntwo.proto's "service FntwoService".
+var FntwoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fntwo.FntwoService",
+	HandlerType: (*FntwoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "SetReceiver", Handler: _FntwoService_SetReceiver_Handler},
+		{MethodName: "GetAppConfig", Handler: _FntwoService_GetAppConfig_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "StreamModel", Handler: _FntwoService_StreamModel_Handler, ServerStreams: true},
+		{StreamName: "SyncCamera", Handler: _FntwoService_SyncCamera_Handler, ServerStreams: true, ClientStreams: true},
+	},
+	Metadata: "fntwo.proto",
+}
+
+// RegisterFntwoServiceServer registers srv with s, the same way
+// protoc-gen-go-grpc's generated RegisterFntwoServiceServer would.
+func RegisterFntwoServiceServer(s grpc.ServiceRegistrar, srv FntwoServiceServer) {
+	s.RegisterService(&FntwoService_ServiceDesc, srv)
+}
@@ -0,0 +1,95 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package fntwopb holds the message and service types described by
+// ../fntwo.proto. protoc is not available in every environment this repo
+// is built in, so these are hand-written to the same shapes protoc-gen-go
+// and protoc-gen-go-grpc would produce, rather than committing generated
+// output that can't be regenerated here. See codec.go for how they're put
+// on the wire without the usual protobuf runtime - a client built against
+// this package must pass JSONCallOption() on every call, since these
+// types don't implement proto.Message and can't be marshaled by grpc-go's
+// real default codec.
+package fntwopb
+
+// QuaternionRotation mirrors the proto message of the same name.
+type QuaternionRotation struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	W float64 `json:"w"`
+}
+
+// Rotation mirrors the proto message of the same name.
+type Rotation struct {
+	Quaternion *QuaternionRotation `json:"quaternion"`
+}
+
+// Bone mirrors the proto message of the same name.
+type Bone struct {
+	Rotation *Rotation `json:"rotation"`
+}
+
+// BlendShape mirrors the proto message of the same name.
+type BlendShape struct {
+	Value float64 `json:"value"`
+}
+
+// VRM mirrors the proto message of the same name.
+type VRM struct {
+	Bones       map[string]*Bone       `json:"bones"`
+	BlendShapes map[string]*BlendShape `json:"blend_shapes"`
+}
+
+// Position mirrors the proto message of the same name.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// Camera mirrors the proto message of the same name.
+type Camera struct {
+	Fov      float64             `json:"fov"`
+	Rotation *QuaternionRotation `json:"rotation"`
+	Position *Position           `json:"position"`
+}
+
+// StreamModelRequest mirrors the proto message of the same name.
+type StreamModelRequest struct {
+	FrequencyHz int32 `json:"frequency_hz"`
+}
+
+// SetReceiverRequest mirrors the proto message of the same name.
+type SetReceiverRequest struct {
+	Name string `json:"name"`
+}
+
+// SetReceiverResponse mirrors the proto message of the same name.
+type SetReceiverResponse struct {
+	Ok bool `json:"ok"`
+}
+
+// GetAppConfigRequest mirrors the proto message of the same name.
+type GetAppConfigRequest struct{}
+
+// AppConfig mirrors the proto message of the same name.
+type AppConfig struct {
+	VmcListenIp          string `json:"vmc_listen_ip"`
+	VmcListenPort        int32  `json:"vmc_listen_port"`
+	ModelUpdateFrequency int32  `json:"model_update_frequency"`
+}
@@ -0,0 +1,180 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package grpcapi exposes the same camera, model, and receiver-switching
+// flows that router.New serves over WebSocket, as a gRPC service, so
+// browser-less consumers get a typed, back-pressured alternative to
+// parsing JSON off a WebSocket. It reuses the existing pool.Pool for
+// fan-out, so WebSocket and gRPC clients observe the same state.
+//
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative fntwo.proto
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/thatpix3l/fntwo/config"
+	"github.com/thatpix3l/fntwo/obj"
+	"github.com/thatpix3l/fntwo/pkg/grpcapi/fntwopb"
+	"github.com/thatpix3l/fntwo/pkg/metrics"
+	"github.com/thatpix3l/fntwo/pool"
+	"github.com/thatpix3l/fntwo/receivers"
+)
+
+// server implements fntwopb.FntwoServiceServer, backed by the same
+// cameraPool and active-receiver state router.New's WebSocket routes use,
+// so WebSocket and gRPC clients observe the same state. It takes that
+// state as plain parameters rather than importing package router, so
+// router can own calling New and Serve without an import cycle.
+type server struct {
+	fntwopb.UnimplementedFntwoServiceServer
+
+	appCfg      *config.App
+	cameraPool  *pool.Pool
+	receiverMap map[string]*receivers.MotionReceiver
+	getActive   func() *receivers.MotionReceiver
+	setActive   func(*receivers.MotionReceiver)
+}
+
+// New builds the gRPC server for FntwoService. cameraPool must be the same
+// pool.Pool instance the /client/camera WebSocket route uses, and
+// receiverMap the same map passed to router.New, so both transports see
+// the same clients and sources. getActive/setActive read and swap the
+// process's active MotionReceiver.
+func New(appCfg *config.App, cameraPool *pool.Pool, receiverMap map[string]*receivers.MotionReceiver, getActive func() *receivers.MotionReceiver, setActive func(*receivers.MotionReceiver)) *grpc.Server {
+
+	s := &server{
+		appCfg:      appCfg,
+		cameraPool:  cameraPool,
+		receiverMap: receiverMap,
+		getActive:   getActive,
+		setActive:   setActive,
+	}
+
+	grpcServer := grpc.NewServer()
+	fntwopb.RegisterFntwoServiceServer(grpcServer, s)
+
+	return grpcServer
+
+}
+
+// Serve starts grpcServer listening on addr. Intended to be run in its own
+// goroutine alongside the HTTP router, on a separate port configured via
+// --grpc-ip/--grpc-port.
+func Serve(grpcServer *grpc.Server, addr string) error {
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Serving gRPC API on %q", addr)
+	return grpcServer.Serve(listener)
+
+}
+
+// StreamModel pushes live VRM frames to the client at the requested
+// frequency, the gRPC equivalent of the /client/model WebSocket loop.
+func (s *server) StreamModel(req *fntwopb.StreamModelRequest, stream fntwopb.FntwoService_StreamModelServer) error {
+
+	frequency := int(req.FrequencyHz)
+	if frequency <= 0 {
+		frequency = s.appCfg.ModelUpdateFrequency
+	}
+
+	for {
+
+		tickStart := time.Now()
+
+		var sendErr error
+		s.getActive().VRM.Read(func(vrm *obj.VRM) {
+			sendErr = stream.Send(toProtoVRM(vrm))
+		})
+		if sendErr != nil {
+			return sendErr
+		}
+
+		time.Sleep(time.Duration(1e9 / frequency))
+		metrics.Default().ObserveModelTick(time.Since(tickStart))
+
+	}
+
+}
+
+// SyncCamera mirrors the bi-directional /client/camera WebSocket: every
+// Camera received from this client is pushed into the shared cameraPool,
+// and every update relayed by the pool is sent back out on the stream.
+func (s *server) SyncCamera(stream fntwopb.FntwoService_SyncCameraServer) error {
+
+	client := s.cameraPool.Create(func(relayedData interface{}) {
+		camera, ok := relayedData.(obj.Camera)
+		if !ok {
+			log.Fatal("Severe error from type asserting camera pool data as obj.Camera! Exiting...")
+		}
+		if err := stream.Send(toProtoCamera(&camera)); err != nil {
+			log.Println(err)
+		}
+	})
+	defer client.Delete()
+
+	for {
+		protoCamera, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		camera, err := fromProtoCamera(protoCamera)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		s.cameraPool.Update(camera)
+	}
+
+}
+
+// SetReceiver mirrors POST /api/receiver/change.
+func (s *server) SetReceiver(ctx context.Context, req *fntwopb.SetReceiverRequest) (*fntwopb.SetReceiverResponse, error) {
+
+	receiver, ok := s.receiverMap[req.Name]
+	if !ok {
+		log.Printf("\"%s\" does not exist!", req.Name)
+		return &fntwopb.SetReceiverResponse{Ok: false}, nil
+	}
+
+	s.setActive(receiver)
+	log.Printf("Successfully changed the active receiver to %s", req.Name)
+
+	return &fntwopb.SetReceiverResponse{Ok: true}, nil
+
+}
+
+// GetAppConfig mirrors GET /api/config/app.
+func (s *server) GetAppConfig(ctx context.Context, req *fntwopb.GetAppConfigRequest) (*fntwopb.AppConfig, error) {
+
+	return &fntwopb.AppConfig{
+		VmcListenIp:          s.appCfg.VmcListenIP,
+		VmcListenPort:        int32(s.appCfg.VmcListenPort),
+		ModelUpdateFrequency: int32(s.appCfg.ModelUpdateFrequency),
+	}, nil
+
+}
@@ -0,0 +1,238 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package metrics centralizes Prometheus instrumentation for fntwo.
+//
+// Subsystems (receivers, WebSocket pools, ...) don't touch Prometheus
+// directly. Instead, they report into a single Reporter, the same way
+// each subsystem of dubbo-go calls into its metrics Reporter instead of
+// registering its own collectors. This keeps the router free of
+// per-receiver instrumentation details and lets future receivers report
+// in without router.New knowing anything about them.
+package metrics
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Reporter is the single entry point every subsystem calls into to record
+// what it's doing. When metrics are disabled, a Reporter is still handed
+// out, but every recording method on it is a no-op.
+type Reporter struct {
+	enabled bool
+
+	framesParsed     *prometheus.CounterVec
+	blendShapeWrites *prometheus.CounterVec
+	boneWrites       *prometheus.CounterVec
+	tcpConnects      *prometheus.CounterVec
+	tcpDisconnects   *prometheus.CounterVec
+	parseErrors      *prometheus.CounterVec
+	wsClients        *prometheus.GaugeVec
+	modelTickSeconds *prometheus.HistogramVec
+}
+
+// current is the process-wide Reporter, set once by Init and read by
+// Default. Subsystems that have no access to the app config (receivers,
+// pools) call Default() instead of threading a Reporter through every
+// constructor.
+var current = &Reporter{}
+
+// Init creates the process-wide Reporter and makes it available through
+// Default. It should be called once, from router.New, before any
+// instrumented subsystem starts.
+func Init(enabled bool) *Reporter {
+	current = New(enabled)
+	return current
+}
+
+// Default returns the process-wide Reporter set up by Init. Before Init
+// is called, it returns a disabled Reporter, so calling any of its
+// methods is always safe.
+func Default() *Reporter {
+	return current
+}
+
+// New creates a Reporter. If enabled is false, the returned Reporter does
+// not register any collectors and every recording method is a no-op, so
+// callers don't need to branch on whether metrics are turned on.
+func New(enabled bool) *Reporter {
+
+	r := &Reporter{enabled: enabled}
+	if !enabled {
+		return r
+	}
+
+	r.framesParsed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fntwo",
+		Subsystem: "receiver",
+		Name:      "frames_parsed_total",
+		Help:      "Number of motion data frames parsed, per receiver.",
+	}, []string{"receiver"})
+
+	r.blendShapeWrites = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fntwo",
+		Subsystem: "receiver",
+		Name:      "blend_shape_writes_total",
+		Help:      "Number of blend shape writes, per receiver and key.",
+	}, []string{"receiver", "key"})
+
+	r.boneWrites = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fntwo",
+		Subsystem: "receiver",
+		Name:      "bone_writes_total",
+		Help:      "Number of bone writes, per receiver and key.",
+	}, []string{"receiver", "key"})
+
+	r.tcpConnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fntwo",
+		Subsystem: "receiver",
+		Name:      "tcp_connects_total",
+		Help:      "Number of accepted TCP connections, per receiver.",
+	}, []string{"receiver"})
+
+	r.tcpDisconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fntwo",
+		Subsystem: "receiver",
+		Name:      "tcp_disconnects_total",
+		Help:      "Number of TCP disconnects, per receiver.",
+	}, []string{"receiver"})
+
+	r.parseErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fntwo",
+		Subsystem: "receiver",
+		Name:      "parse_errors_total",
+		Help:      "Number of frame parse errors, per receiver.",
+	}, []string{"receiver"})
+
+	r.wsClients = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "fntwo",
+		Subsystem: "router",
+		Name:      "ws_clients",
+		Help:      "Current number of connected WebSocket clients, per route.",
+	}, []string{"route"})
+
+	r.modelTickSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "fntwo",
+		Subsystem: "router",
+		Name:      "model_broadcast_tick_seconds",
+		Help:      "Duration of a single model-broadcast loop tick, across /client/model and gRPC StreamModel.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{})
+
+	return r
+
+}
+
+// Handler returns the HTTP handler that serves the Prometheus exposition
+// format. Safe to mount even when metrics are disabled.
+func (r *Reporter) Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Serve starts an HTTP server exposing r's Handler at /metrics on addr.
+// Intended to be run in its own goroutine alongside the main router, on
+// the separate address configured via --metrics-listen-addr, so scraping
+// Prometheus doesn't require exposing the rest of the API.
+func Serve(r *Reporter, addr string) error {
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.Handler())
+
+	log.Printf("Serving Prometheus metrics on %q", addr)
+	return http.ListenAndServe(addr, mux)
+
+}
+
+// FrameParsed records that a receiver finished parsing one frame of motion data.
+func (r *Reporter) FrameParsed(receiver string) {
+	if !r.enabled {
+		return
+	}
+	r.framesParsed.WithLabelValues(receiver).Inc()
+}
+
+// BlendShapeWritten records a blend shape write for a given receiver and key.
+func (r *Reporter) BlendShapeWritten(receiver, key string) {
+	if !r.enabled {
+		return
+	}
+	r.blendShapeWrites.WithLabelValues(receiver, key).Inc()
+}
+
+// BoneWritten records a bone write for a given receiver and key.
+func (r *Reporter) BoneWritten(receiver, key string) {
+	if !r.enabled {
+		return
+	}
+	r.boneWrites.WithLabelValues(receiver, key).Inc()
+}
+
+// TCPConnect records a newly accepted TCP connection for a receiver.
+func (r *Reporter) TCPConnect(receiver string) {
+	if !r.enabled {
+		return
+	}
+	r.tcpConnects.WithLabelValues(receiver).Inc()
+}
+
+// TCPDisconnect records a TCP connection ending for a receiver.
+func (r *Reporter) TCPDisconnect(receiver string) {
+	if !r.enabled {
+		return
+	}
+	r.tcpDisconnects.WithLabelValues(receiver).Inc()
+}
+
+// ParseError records a frame that failed to parse for a receiver.
+func (r *Reporter) ParseError(receiver string) {
+	if !r.enabled {
+		return
+	}
+	r.parseErrors.WithLabelValues(receiver).Inc()
+}
+
+// ClientConnected increments the connected WebSocket client gauge for a route.
+func (r *Reporter) ClientConnected(route string) {
+	if !r.enabled {
+		return
+	}
+	r.wsClients.WithLabelValues(route).Inc()
+}
+
+// ClientDisconnected decrements the connected WebSocket client gauge for a route.
+func (r *Reporter) ClientDisconnected(route string) {
+	if !r.enabled {
+		return
+	}
+	r.wsClients.WithLabelValues(route).Dec()
+}
+
+// ObserveModelTick records how long one iteration of a model-broadcast
+// loop took, including its sleep until the next frame. Both the
+// /client/model WebSocket route and the gRPC StreamModel loop report here.
+func (r *Reporter) ObserveModelTick(d time.Duration) {
+	if !r.enabled {
+		return
+	}
+	r.modelTickSeconds.WithLabelValues().Observe(d.Seconds())
+}
@@ -27,11 +27,15 @@ import (
 	"time"
 
 	"github.com/thatpix3l/fntwo/pkg/config"
+	"github.com/thatpix3l/fntwo/pkg/metrics"
 	"github.com/thatpix3l/fntwo/pkg/obj"
 	"github.com/thatpix3l/fntwo/pkg/receivers"
 	"github.com/westphae/quaternion"
 )
 
+// receiverName identifies this receiver in metric labels.
+const receiverName = "facemotion3d"
+
 var (
 	fm3dReceiver  *receivers.MotionReceiver
 	serverEnabled = true
@@ -74,6 +78,7 @@ func parseFrame(frameStr string) {
 			// Blend shape value
 			value, err := strconv.ParseFloat(singlePayload[1], 64)
 			if err != nil {
+				metrics.Default().ParseError(receiverName)
 				continue
 			}
 
@@ -84,6 +89,7 @@ func parseFrame(frameStr string) {
 			blendShape := obj.BlendShape(value)
 
 			fm3dReceiver.VRM.WriteBlendShape(key, blendShape)
+			metrics.Default().BlendShapeWritten(receiverName, key)
 
 		}
 
@@ -139,11 +145,14 @@ func parseFrame(frameStr string) {
 			}
 
 			fm3dReceiver.VRM.WriteBone(key, bone)
+			metrics.Default().BoneWritten(receiverName, key)
 
 		}
 
 	}
 
+	metrics.Default().FrameParsed(receiverName)
+
 }
 
 // Tell a device with address to send the Facemotion3D data through TCP
@@ -195,6 +204,7 @@ func listenTCP() {
 			log.Println(err)
 		} else {
 			currentConn = conn
+			metrics.Default().TCPConnect(receiverName)
 		}
 
 		log.Print("Accepted new Facemotion3D client")
@@ -206,6 +216,7 @@ func listenTCP() {
 			connBuf := make([]byte, 8192)
 			_, err := currentConn.Read(connBuf)
 			if err != nil {
+				metrics.Default().TCPDisconnect(receiverName)
 				break
 			}
 			liveFrames += string(connBuf)
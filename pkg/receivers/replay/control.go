@@ -0,0 +1,73 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package replay
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/thatpix3l/fntwo/pkg/receivers"
+)
+
+// activeRecorder is the process-wide Recorder controlled by the
+// /api/replay/record/{start,stop} routes. Only one recording can be in
+// progress at a time.
+var (
+	recorderMu     sync.Mutex
+	activeRecorder *Recorder
+)
+
+// StartRecording begins recording source's VRM state to path. It fails if
+// a recording is already in progress.
+func StartRecording(source *receivers.MotionReceiver, path string) error {
+
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+
+	if activeRecorder != nil {
+		return fmt.Errorf("a recording is already in progress")
+	}
+
+	rec, err := NewRecorder(path)
+	if err != nil {
+		return err
+	}
+
+	rec.Start(source)
+	activeRecorder = rec
+
+	return nil
+
+}
+
+// StopRecording ends the in-progress recording, if any.
+func StopRecording() error {
+
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+
+	if activeRecorder == nil {
+		return fmt.Errorf("no recording is in progress")
+	}
+
+	err := activeRecorder.Stop()
+	activeRecorder = nil
+
+	return err
+
+}
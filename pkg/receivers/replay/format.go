@@ -0,0 +1,189 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// frameKind identifies what a recorded frame carries.
+type frameKind uint64
+
+const (
+	// kindDictEntry assigns a short integer id to a key name the first
+	// time that key is recorded, so every later frame for that key only
+	// has to carry the id instead of the full name.
+	kindDictEntry frameKind = iota
+	kindBlend
+	kindBone
+)
+
+// magic is written once at the start of every recording file.
+var magic = [4]byte{'F', 'N', '2', 'R'}
+
+// formatVersion guards against reading a recording written by an
+// incompatible version of this package.
+const formatVersion = 1
+
+// writeHeader writes the file magic and format version.
+func writeHeader(w io.Writer) error {
+	if _, err := w.Write(magic[:]); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, uint8(formatVersion))
+}
+
+// readHeader validates the file magic and format version.
+func readHeader(r io.Reader) error {
+
+	var gotMagic [4]byte
+	if _, err := io.ReadFull(r, gotMagic[:]); err != nil {
+		return fmt.Errorf("reading recording header: %w", err)
+	}
+	if gotMagic != magic {
+		return fmt.Errorf("not an fntwo replay recording")
+	}
+
+	var version uint8
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return err
+	}
+	if version != formatVersion {
+		return fmt.Errorf("recording is format version %d, this build supports %d", version, formatVersion)
+	}
+
+	return nil
+
+}
+
+// writeFrame writes a single frame:
+// varint(ts_ns_delta) | varint(kind) | varint(key_id) | payload.
+// The frame carries no overall length; readFrame derives the payload size
+// from kind, and a dictionary entry's payload carries its own name length.
+func writeFrame(w *bufio.Writer, tsDeltaNs int64, kind frameKind, keyID uint64, payload []byte) error {
+
+	var buf [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(buf[:], uint64(tsDeltaNs))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+
+	n = binary.PutUvarint(buf[:], uint64(kind))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+
+	n = binary.PutUvarint(buf[:], keyID)
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+
+	_, err := w.Write(payload)
+	return err
+
+}
+
+// readFrame reads a single frame written by writeFrame. io.EOF is returned
+// unwrapped when there are no more frames.
+func readFrame(r *bufio.Reader) (tsDeltaNs int64, kind frameKind, keyID uint64, payload []byte, err error) {
+
+	rawTsDelta, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+
+	rawKind, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+
+	keyID, err = binary.ReadUvarint(r)
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+
+	kind = frameKind(rawKind)
+
+	var payloadLen int
+	switch kind {
+	case kindBlend:
+		payloadLen = 8 // one float64
+	case kindBone:
+		payloadLen = 32 // four float64 (x, y, z, w)
+	case kindDictEntry:
+		nameLen, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, 0, 0, nil, err
+		}
+		payloadLen = int(nameLen)
+	default:
+		return 0, 0, 0, nil, fmt.Errorf("unknown frame kind %d in recording", kind)
+	}
+
+	payload = make([]byte, payloadLen)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, 0, 0, nil, err
+	}
+
+	return int64(rawTsDelta), kind, keyID, payload, nil
+
+}
+
+// encodeDictEntry builds the payload for a kindDictEntry frame: the key
+// name, prefixed with its own varint length so readFrame can size the read.
+func encodeDictEntry(name string) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(name)))
+	return append(buf[:n], []byte(name)...)
+}
+
+// encodeBlend packs a single blend shape weight into 8 bytes.
+func encodeBlend(value float64) []byte {
+	var payload [8]byte
+	binary.LittleEndian.PutUint64(payload[:], math.Float64bits(value))
+	return payload[:]
+}
+
+// decodeBlend unpacks a blend shape weight encoded by encodeBlend.
+func decodeBlend(payload []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(payload))
+}
+
+// encodeBone packs a bone's quaternion rotation into 32 bytes.
+func encodeBone(x, y, z, w float64) []byte {
+	var payload [32]byte
+	binary.LittleEndian.PutUint64(payload[0:8], math.Float64bits(x))
+	binary.LittleEndian.PutUint64(payload[8:16], math.Float64bits(y))
+	binary.LittleEndian.PutUint64(payload[16:24], math.Float64bits(z))
+	binary.LittleEndian.PutUint64(payload[24:32], math.Float64bits(w))
+	return payload[:]
+}
+
+// decodeBone unpacks a bone's quaternion rotation encoded by encodeBone.
+func decodeBone(payload []byte) (x, y, z, w float64) {
+	x = math.Float64frombits(binary.LittleEndian.Uint64(payload[0:8]))
+	y = math.Float64frombits(binary.LittleEndian.Uint64(payload[8:16]))
+	z = math.Float64frombits(binary.LittleEndian.Uint64(payload[16:24]))
+	w = math.Float64frombits(binary.LittleEndian.Uint64(payload[24:32]))
+	return
+}
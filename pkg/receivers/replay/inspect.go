@@ -0,0 +1,155 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package replay
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"time"
+)
+
+// Summary describes the contents of a recording, for offline inspection.
+type Summary struct {
+	Duration   time.Duration
+	FrameCount int
+	Keys       []string
+}
+
+// Inspect reads through a recording at path and summarizes it, without
+// applying any of its frames to a VRM.
+func Inspect(path string) (Summary, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	if err := readHeader(reader); err != nil {
+		return Summary{}, err
+	}
+
+	var summary Summary
+	var elapsed time.Duration
+
+	for {
+
+		tsDelta, kind, keyID, payload, err := readFrame(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Summary{}, err
+		}
+
+		elapsed += time.Duration(tsDelta)
+		summary.FrameCount++
+
+		if kind == kindDictEntry {
+			_ = keyID
+			summary.Keys = append(summary.Keys, string(payload))
+		}
+
+	}
+
+	summary.Duration = elapsed
+
+	return summary, nil
+
+}
+
+// Trim reads through a recording at srcPath and writes a new recording to
+// dstPath containing only the frames between startSeconds and endSeconds
+// (0 means "the end of the recording"), with timestamps rebased so
+// playback of the trimmed file starts immediately.
+func Trim(srcPath, dstPath string, startSeconds, endSeconds float64) error {
+
+	start := time.Duration(startSeconds * float64(time.Second))
+	end := time.Duration(endSeconds * float64(time.Second))
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	reader := bufio.NewReader(src)
+	if err := readHeader(reader); err != nil {
+		return err
+	}
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	writer := bufio.NewWriter(dst)
+	if err := writeHeader(writer); err != nil {
+		return err
+	}
+
+	var elapsed time.Duration
+
+	// lastKeptTsNs is the baseline the next written frame's delta is
+	// measured against. It starts at start itself, not zero, so the first
+	// motion frame inside [start, end] gets a small or zero delta - the
+	// dictionary entries kept ahead of it are almost always near t=0 and
+	// must not be what "immediately" gets measured from.
+	lastKeptTsNs := start.Nanoseconds()
+
+	for {
+
+		tsDelta, kind, keyID, payload, err := readFrame(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		elapsed += time.Duration(tsDelta)
+
+		// Dictionary entries are always kept, so the key dictionary stays
+		// valid no matter where the trimmed range begins.
+		inRange := kind == kindDictEntry || (elapsed >= start && (end == 0 || elapsed <= end))
+		if !inRange {
+			continue
+		}
+
+		// Dictionary entries must be applied before real playback begins,
+		// so write them with no delay and don't let them shift the
+		// baseline the first motion frame's delta is measured against.
+		var tsDeltaOut int64
+		if kind != kindDictEntry {
+			tsNs := elapsed.Nanoseconds()
+			tsDeltaOut = tsNs - lastKeptTsNs
+			lastKeptTsNs = tsNs
+		}
+
+		if err := writeFrame(writer, tsDeltaOut, kind, keyID, payload); err != nil {
+			return err
+		}
+
+	}
+
+	return writer.Flush()
+
+}
@@ -0,0 +1,354 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package replay implements a MotionReceiver that plays a recording made
+// by Recorder back into the VRM, at real time, a configurable speed, or
+// within a loop range, so users can debug rigs or produce reproducible
+// demo streams without a phone attached.
+package replay
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/thatpix3l/fntwo/pkg/config"
+	"github.com/thatpix3l/fntwo/pkg/metrics"
+	"github.com/thatpix3l/fntwo/pkg/obj"
+	"github.com/thatpix3l/fntwo/pkg/receivers"
+)
+
+// receiverName identifies this receiver in metric labels.
+const receiverName = "replay"
+
+// playRequest is sent on playCh to (re)start playback from scratch.
+type playRequest struct {
+	path      string
+	speed     float64
+	loopStart time.Duration
+	loopEnd   time.Duration // zero means "play to end, no loop"
+}
+
+// seekRequest is sent on seekCh to jump to a position in the current playback.
+type seekRequest struct {
+	to time.Duration
+}
+
+var (
+	replayReceiver *receivers.MotionReceiver
+	serverEnabled  = true
+
+	playCh = make(chan playRequest, 1)
+	seekCh = make(chan seekRequest, 1)
+)
+
+// Play (re)starts playback of the recording at path, at the given speed
+// (1.0 is real time), looping between loopStart and loopEnd if loopEnd is
+// non-zero. It replaces whatever is currently playing.
+func Play(path string, speed float64, loopStart, loopEnd time.Duration) {
+	if speed <= 0 {
+		speed = 1
+	}
+	playCh <- playRequest{path: path, speed: speed, loopStart: loopStart, loopEnd: loopEnd}
+}
+
+// Seek jumps the current playback to position to, reconstructing state by
+// replaying every frame up to that point before resuming in real time.
+func Seek(to time.Duration) {
+	seekCh <- seekRequest{to: to}
+}
+
+// listen runs the playback loop for the lifetime of the receiver, applying
+// whatever the most recent Play/Seek request asked for.
+func listen() {
+
+	var current *playback
+
+	for serverEnabled {
+
+		select {
+
+		case req := <-playCh:
+
+			if current != nil {
+				current.stop()
+			}
+
+			p, err := newPlayback(req)
+			if err != nil {
+				log.Printf("Failed to start replay of %q: %s", req.path, err)
+				continue
+			}
+			current = p
+			go current.run()
+
+		case seek := <-seekCh:
+
+			if current == nil {
+				log.Print("Received a replay seek with nothing playing")
+				continue
+			}
+			current.requestSeek(seek.to)
+
+		}
+
+	}
+
+	if current != nil {
+		current.stop()
+	}
+
+}
+
+func stopListening() {
+	serverEnabled = false
+}
+
+// New creates a new MotionReceiver that plays back recordings made by a
+// Recorder, instead of receiving motion data live.
+func New(appConfig *config.App) *receivers.MotionReceiver {
+	replayReceiver = receivers.New(appConfig, listen, stopListening)
+	return replayReceiver
+}
+
+// playback owns one open recording file being streamed into the VRM.
+// file, reader, and keyName are only ever touched from the goroutine
+// running run(); requestSeek hands a seek over to that goroutine through
+// seekCh instead of mutating them directly, so nothing else needs to
+// synchronize on them.
+type playback struct {
+	req     playRequest
+	file    *os.File
+	reader  *bufio.Reader
+	keyName map[uint64]string
+	done    chan struct{}
+	seekCh  chan time.Duration
+}
+
+func newPlayback(req playRequest) (*playback, error) {
+
+	file, err := os.Open(req.path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(file)
+	if err := readHeader(reader); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	p := &playback{
+		req:     req,
+		file:    file,
+		reader:  reader,
+		keyName: make(map[uint64]string),
+		done:    make(chan struct{}),
+		seekCh:  make(chan time.Duration, 1),
+	}
+
+	if req.loopStart > 0 {
+		p.fastForwardTo(req.loopStart)
+	}
+
+	return p, nil
+
+}
+
+// fastForwardTo reconstructs VRM state as of position `to` by applying
+// every frame up to that point without pacing, then leaves the reader
+// positioned to resume real-time playback from there.
+func (p *playback) fastForwardTo(to time.Duration) {
+
+	var elapsed time.Duration
+	for elapsed < to {
+		tsDelta, kind, keyID, payload, err := readFrame(p.reader)
+		if err != nil {
+			return
+		}
+		elapsed += time.Duration(tsDelta)
+		p.apply(kind, keyID, payload)
+	}
+
+}
+
+// requestSeek asks the goroutine running run() to seek to `to`. Seeking
+// reopens the recording and replays every frame up to `to`, which touches
+// the same file/reader/keyName that run()'s read loop is using, so it must
+// happen on run()'s own goroutine rather than here.
+func (p *playback) requestSeek(to time.Duration) {
+	select {
+	case p.seekCh <- to:
+	default:
+		// A seek is already queued; run() hasn't picked it up yet. Drop
+		// this one rather than block - the queued seek will land shortly
+		// and a caller wanting this exact position can always seek again.
+	}
+}
+
+// doSeek re-reads the recording from the start up to `to`, applying every
+// frame along the way so the VRM reflects exactly what it would at that
+// position, then resumes streaming from there. Only ever called from
+// run()'s own goroutine.
+func (p *playback) doSeek(to time.Duration) {
+
+	file, err := os.Open(p.req.path)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	reader := bufio.NewReader(file)
+	if err := readHeader(reader); err != nil {
+		log.Print(err)
+		file.Close()
+		return
+	}
+
+	p.file.Close()
+	p.file = file
+	p.reader = reader
+	p.keyName = make(map[uint64]string)
+
+	p.fastForwardTo(to)
+
+}
+
+func (p *playback) apply(kind frameKind, keyID uint64, payload []byte) {
+
+	switch kind {
+
+	case kindDictEntry:
+		p.keyName[keyID] = string(payload)
+
+	case kindBlend:
+		key, ok := p.keyName[keyID]
+		if !ok {
+			log.Printf("Replay frame references unknown key id %d", keyID)
+			return
+		}
+		replayReceiver.VRM.WriteBlendShape(key, obj.BlendShape(decodeBlend(payload)))
+		metrics.Default().BlendShapeWritten(receiverName, key)
+
+	case kindBone:
+		key, ok := p.keyName[keyID]
+		if !ok {
+			log.Printf("Replay frame references unknown key id %d", keyID)
+			return
+		}
+		x, y, z, w := decodeBone(payload)
+		replayReceiver.VRM.WriteBone(key, obj.Bone{
+			Rotation: obj.Rotation{
+				Quaternion: obj.QuaternionRotation{X: x, Y: y, Z: z, W: w},
+			},
+		})
+		metrics.Default().BoneWritten(receiverName, key)
+
+	}
+
+}
+
+// run streams frames from the current position in real time (scaled by
+// speed), looping between loopStart and loopEnd when loopEnd is set, until
+// stop is called or the file is exhausted.
+func (p *playback) run() {
+
+	// A plain "defer p.file.Close()" would bind to whatever p.file is right
+	// now, not whatever it is when run() returns. doSeek below closes and
+	// reassigns p.file on every loop and every external seek, so that would
+	// close the first file a second time and leak whichever file is open
+	// when run() returns. Deferring the call itself, instead of its
+	// bound method value, reads p.file at return time.
+	defer func() {
+		p.file.Close()
+	}()
+
+	var elapsed time.Duration
+
+	for {
+
+		select {
+		case <-p.done:
+			return
+		case to := <-p.seekCh:
+			p.doSeek(to)
+			elapsed = to
+			continue
+		default:
+		}
+
+		tsDelta, kind, keyID, payload, err := readFrame(p.reader)
+		if err != nil {
+
+			if p.req.loopEnd > 0 {
+				p.doSeek(p.req.loopStart)
+				elapsed = p.req.loopStart
+				continue
+			}
+
+			return
+
+		}
+
+		// A plain time.Sleep(wait) here doesn't notice p.done/p.seekCh
+		// until it returns. Recorder only logs a frame on change, so a
+		// static rig can leave wait tens of seconds long; during that
+		// stretch a new Play() or Stop() wouldn't preempt this goroutine,
+		// which would keep writing to the shared VRM after the new
+		// playback had already started. Select on the wait instead so a
+		// pending done/seek interrupts it immediately.
+		wait := time.Duration(float64(tsDelta) / p.req.speed)
+		select {
+		case <-p.done:
+			return
+		case to := <-p.seekCh:
+			p.doSeek(to)
+			elapsed = to
+			continue
+		case <-time.After(wait):
+		}
+		elapsed += time.Duration(tsDelta)
+
+		p.apply(kind, keyID, payload)
+		metrics.Default().FrameParsed(receiverName)
+
+		if p.req.loopEnd > 0 && elapsed >= p.req.loopEnd {
+			p.doSeek(p.req.loopStart)
+			elapsed = p.req.loopStart
+		}
+
+	}
+
+}
+
+func (p *playback) stop() {
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+}
+
+// String describes the currently configured loop range, for log messages.
+func (req playRequest) String() string {
+	if req.loopEnd == 0 {
+		return fmt.Sprintf("%s (no loop)", req.path)
+	}
+	return fmt.Sprintf("%s (looping %s-%s)", req.path, req.loopStart, req.loopEnd)
+}
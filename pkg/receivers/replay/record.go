@@ -0,0 +1,207 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package replay
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thatpix3l/fntwo/pkg/obj"
+	"github.com/thatpix3l/fntwo/pkg/receivers"
+)
+
+// sampleRate is how often the Recorder samples the source receiver's VRM
+// state looking for changes. obj.VRM does not currently expose a write
+// hook, so instead of intercepting WriteBlendShape/WriteBone calls
+// directly, the Recorder samples fast enough that no change at the
+// frontend's own update rate is missed.
+//
+// This is a real tradeoff, not just a performance detail: two writes to
+// the same key between samples (e.g. a blend shape set then reset before
+// the next tick) are indistinguishable from no write at all and are
+// silently dropped from the recording. Raising sampleRate narrows the
+// window but can't close it. A recording is a faithful sampling of VRM
+// state at sampleRate Hz, not a faithful log of every write the source
+// receiver made.
+const sampleRate = 240
+
+// Recorder samples a source MotionReceiver's VRM state and appends every
+// changed blend shape or bone to a compact on-disk log, so a rig session
+// can be played back later without a phone attached.
+type Recorder struct {
+	mu sync.Mutex
+
+	file   *os.File
+	writer *bufio.Writer
+
+	keyIDs map[string]uint64
+	nextID uint64
+
+	lastBlend map[string]float64
+	lastBone  map[string][4]float64
+
+	startedAt time.Time
+	lastTsNs  int64
+
+	stop chan struct{}
+}
+
+// NewRecorder creates (truncating, if it already exists) the recording
+// file at path and writes its header.
+func NewRecorder(path string) (*Recorder, error) {
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := bufio.NewWriter(file)
+	if err := writeHeader(writer); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Recorder{
+		file:      file,
+		writer:    writer,
+		keyIDs:    make(map[string]uint64),
+		lastBlend: make(map[string]float64),
+		lastBone:  make(map[string][4]float64),
+	}, nil
+
+}
+
+// keyID returns the id assigned to name, writing a dictionary entry frame
+// the first time name is seen.
+func (rec *Recorder) keyID(name string, tsNs int64) (uint64, error) {
+
+	if id, ok := rec.keyIDs[name]; ok {
+		return id, nil
+	}
+
+	id := rec.nextID
+	rec.nextID++
+	rec.keyIDs[name] = id
+
+	err := writeFrame(rec.writer, tsNs-rec.lastTsNs, kindDictEntry, id, encodeDictEntry(name))
+	rec.lastTsNs = tsNs
+
+	return id, err
+
+}
+
+// Start begins sampling source at sampleRate Hz in a background goroutine,
+// appending a frame for every blend shape or bone whose value changed
+// since the last sample. Start returns immediately; call Stop to end it.
+func (rec *Recorder) Start(source *receivers.MotionReceiver) {
+
+	rec.startedAt = time.Now()
+	rec.stop = make(chan struct{})
+
+	go func() {
+
+		ticker := time.NewTicker(time.Second / sampleRate)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-rec.stop:
+				return
+			case <-ticker.C:
+				source.VRM.Read(rec.sample)
+			}
+		}
+
+	}()
+
+}
+
+// sample diffs the current VRM state against the last recorded state and
+// appends a frame for everything that changed.
+func (rec *Recorder) sample(vrm *obj.VRM) {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	tsNs := time.Since(rec.startedAt).Nanoseconds()
+
+	for key, blendShape := range vrm.BlendShapes {
+
+		value := float64(blendShape)
+		if last, ok := rec.lastBlend[key]; ok && last == value {
+			continue
+		}
+		rec.lastBlend[key] = value
+
+		id, err := rec.keyID(key, tsNs)
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		if err := writeFrame(rec.writer, tsNs-rec.lastTsNs, kindBlend, id, encodeBlend(value)); err != nil {
+			log.Print(err)
+			continue
+		}
+		rec.lastTsNs = tsNs
+
+	}
+
+	for key, bone := range vrm.Bones {
+
+		quat := bone.Rotation.Quaternion
+		current := [4]float64{quat.X, quat.Y, quat.Z, quat.W}
+		if last, ok := rec.lastBone[key]; ok && last == current {
+			continue
+		}
+		rec.lastBone[key] = current
+
+		id, err := rec.keyID(key, tsNs)
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		if err := writeFrame(rec.writer, tsNs-rec.lastTsNs, kindBone, id, encodeBone(current[0], current[1], current[2], current[3])); err != nil {
+			log.Print(err)
+			continue
+		}
+		rec.lastTsNs = tsNs
+
+	}
+
+}
+
+// Stop ends sampling, flushes, and closes the recording file.
+func (rec *Recorder) Stop() error {
+
+	close(rec.stop)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if err := rec.writer.Flush(); err != nil {
+		return err
+	}
+
+	return rec.file.Close()
+
+}
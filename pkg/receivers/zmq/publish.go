@@ -0,0 +1,195 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package zmq
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	zmq4 "github.com/go-zeromq/zmq4"
+	"github.com/thatpix3l/fntwo/pkg/obj"
+	"github.com/thatpix3l/fntwo/pkg/receivers"
+)
+
+// snapshotInterval is how often Start re-publishes a full VRM snapshot, in
+// addition to the one sent immediately on Start. go-zeromq/zmq4's PUB
+// socket exposes no subscriber-connect event the way a true XPUB socket
+// would, so there's no way to publish a snapshot exactly when a
+// subscriber connects, as the request's "on-connect" framing intended.
+// Periodic resyncs are the next best thing: a subscriber that joins
+// between resyncs is at most one snapshotInterval behind until its first
+// full snapshot, rather than permanently out of sync.
+const snapshotInterval = 5 * time.Second
+
+// Publisher mirrors a MotionReceiver's VRM state onto a ZeroMQ PUB socket,
+// so other fntwo instances (or any ZeroMQ subscriber) can chain off of it,
+// e.g. a capture host feeding one or more rendering hosts. It publishes
+// per-key blend/bone envelopes as they change, the same schema the zmq
+// receiver's applyEnvelope consumes, plus periodic full snapshots (see
+// snapshotInterval) so a newly joined subscriber catches up.
+type Publisher struct {
+	source  *receivers.MotionReceiver
+	pub     zmq4.Socket
+	rate    int
+	seq     uint64
+	stopped bool
+
+	lastBlend map[string]float64
+	lastBone  map[string][4]float64
+}
+
+// NewPublisher binds a PUB socket at addr and begins mirroring source's VRM
+// state onto it, rate times per second, once Start is called.
+func NewPublisher(source *receivers.MotionReceiver, addr string, rate int) (*Publisher, error) {
+
+	pub := zmq4.NewPub(context.Background())
+	if err := pub.Listen(addr); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Publishing motion data over ZeroMQ at %q", addr)
+
+	return &Publisher{
+		source:    source,
+		pub:       pub,
+		rate:      rate,
+		lastBlend: make(map[string]float64),
+		lastBone:  make(map[string][4]float64),
+	}, nil
+
+}
+
+// publishSnapshot sends the full current VRM state as a single envelope,
+// so a subscriber that just connected isn't left with partial state.
+func (p *Publisher) publishSnapshot() {
+
+	p.source.VRM.Read(func(vrm *obj.VRM) {
+
+		payload, err := json.Marshal(vrm)
+		if err != nil {
+			log.Print(err)
+			return
+		}
+
+		p.send(envelope{Kind: kindSnapshot, Payload: payload})
+
+	})
+
+}
+
+// publishDeltas diffs the current VRM state against the last published
+// state and sends a per-key blend/bone envelope for everything that
+// changed, mirroring Recorder.sample. As with Recorder, obj.VRM exposes
+// no write hook to intercept directly, so two writes to the same key
+// between samples (e.g. set then reset) are indistinguishable from no
+// write and are silently dropped - raising rate narrows that window but
+// can't close it.
+func (p *Publisher) publishDeltas() {
+
+	p.source.VRM.Read(func(vrm *obj.VRM) {
+
+		for key, blendShape := range vrm.BlendShapes {
+
+			value := float64(blendShape)
+			if last, ok := p.lastBlend[key]; ok && last == value {
+				continue
+			}
+			p.lastBlend[key] = value
+
+			payload, err := json.Marshal(blendShape)
+			if err != nil {
+				log.Print(err)
+				continue
+			}
+			p.send(envelope{Kind: kindBlend, Key: key, Payload: payload})
+
+		}
+
+		for key, bone := range vrm.Bones {
+
+			quat := bone.Rotation.Quaternion
+			current := [4]float64{quat.X, quat.Y, quat.Z, quat.W}
+			if last, ok := p.lastBone[key]; ok && last == current {
+				continue
+			}
+			p.lastBone[key] = current
+
+			payload, err := json.Marshal(bone)
+			if err != nil {
+				log.Print(err)
+				continue
+			}
+			p.send(envelope{Kind: kindBone, Key: key, Payload: payload})
+
+		}
+
+	})
+
+}
+
+// send marshals and writes a single envelope to the PUB socket, stamping it
+// with the next sequence number and the current time.
+func (p *Publisher) send(env envelope) {
+
+	p.seq++
+	env.Seq = p.seq
+	env.Ts = time.Now().UnixNano()
+
+	envBytes, err := json.Marshal(env)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	if err := p.pub.Send(zmq4.NewMsg(envBytes)); err != nil {
+		log.Print(err)
+	}
+
+}
+
+// Start publishes an initial full snapshot, then publishes per-key deltas
+// at the configured rate and a full resync snapshot every
+// snapshotInterval, until Stop is called.
+func (p *Publisher) Start() {
+
+	p.publishSnapshot()
+
+	ticker := time.NewTicker(time.Second / time.Duration(p.rate))
+	defer ticker.Stop()
+
+	resync := time.NewTicker(snapshotInterval)
+	defer resync.Stop()
+
+	for !p.stopped {
+		select {
+		case <-resync.C:
+			p.publishSnapshot()
+		case <-ticker.C:
+			p.publishDeltas()
+		}
+	}
+
+}
+
+// Stop closes the underlying PUB socket and ends the publish loop.
+func (p *Publisher) Stop() {
+	p.stopped = true
+	p.pub.Close()
+}
@@ -0,0 +1,176 @@
+/*
+fntwo: An easy to use tool for VTubing
+Copyright (C) 2022 thatpix3l <contact@thatpix3l.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, version 3 of the License.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package zmq implements a MotionReceiver that subscribes to a ZeroMQ PUB
+// socket instead of parsing a device-specific TCP stream. Unlike
+// facemotion3d, which frames data with a "___FACEMOTION3D" delimiter
+// regex, remote capture rigs publish one JSON envelope per bone or blend
+// shape group, so any number of fntwo instances or third-party publishers
+// can feed motion data without speaking a bespoke line protocol.
+package zmq
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	zmq4 "github.com/go-zeromq/zmq4"
+	"github.com/thatpix3l/fntwo/pkg/config"
+	"github.com/thatpix3l/fntwo/pkg/metrics"
+	"github.com/thatpix3l/fntwo/pkg/obj"
+	"github.com/thatpix3l/fntwo/pkg/receivers"
+)
+
+// receiverName identifies this receiver in metric labels.
+const receiverName = "zmq"
+
+// kind enumerates the payload carried by an envelope.
+type kind string
+
+const (
+	kindBlend    kind = "blend"
+	kindBone     kind = "bone"
+	kindSnapshot kind = "snapshot"
+)
+
+// envelope is the wire format published and consumed on the ZeroMQ socket.
+// A blend/bone envelope carries a single key's update; a snapshot envelope
+// carries a full obj.VRM so a freshly connected subscriber isn't left with
+// partial state until every key has been individually updated at least once.
+type envelope struct {
+	Seq     uint64          `json:"seq"`
+	Ts      int64           `json:"ts"`
+	Kind    kind            `json:"kind"`
+	Key     string          `json:"key,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+var (
+	zmqReceiver   *receivers.MotionReceiver
+	serverEnabled = true
+	subSocket     zmq4.Socket
+)
+
+// applyEnvelope dispatches a single decoded envelope onto the active VRM.
+func applyEnvelope(env envelope) error {
+
+	switch env.Kind {
+
+	case kindBlend:
+		var value obj.BlendShape
+		if err := json.Unmarshal(env.Payload, &value); err != nil {
+			return err
+		}
+		zmqReceiver.VRM.WriteBlendShape(env.Key, value)
+		metrics.Default().BlendShapeWritten(receiverName, env.Key)
+
+	case kindBone:
+		var value obj.Bone
+		if err := json.Unmarshal(env.Payload, &value); err != nil {
+			return err
+		}
+		zmqReceiver.VRM.WriteBone(env.Key, value)
+		metrics.Default().BoneWritten(receiverName, env.Key)
+
+	case kindSnapshot:
+		var vrm obj.VRM
+		if err := json.Unmarshal(env.Payload, &vrm); err != nil {
+			return err
+		}
+		for key, blendShape := range vrm.BlendShapes {
+			zmqReceiver.VRM.WriteBlendShape(key, blendShape)
+		}
+		for key, bone := range vrm.Bones {
+			zmqReceiver.VRM.WriteBone(key, bone)
+		}
+
+	}
+
+	return nil
+
+}
+
+// listenZMQ connects to the configured PUB socket and applies every
+// envelope it receives onto the active VRM until stopListening is called.
+func listenZMQ() {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subSocket = zmq4.NewSub(ctx)
+	defer subSocket.Close()
+
+	addr := zmqReceiver.AppConfig.ZMQSubAddr
+	if err := subSocket.Dial(addr); err != nil {
+		log.Printf("Failed to dial ZeroMQ PUB socket at %q: %s", addr, err)
+		return
+	}
+
+	if err := subSocket.SetOption(zmq4.OptionSubscribe, ""); err != nil {
+		log.Print(err)
+		return
+	}
+
+	log.Printf("Subscribed to ZeroMQ motion source at %q", addr)
+	metrics.Default().TCPConnect(receiverName)
+
+	for serverEnabled {
+
+		msg, err := subSocket.Recv()
+		if err != nil {
+			log.Printf("ZeroMQ subscriber disconnected: %s, retrying in 3 seconds", err)
+			metrics.Default().TCPDisconnect(receiverName)
+			time.Sleep(3 * time.Second)
+			continue
+		}
+
+		var env envelope
+		if err := json.Unmarshal(msg.Bytes(), &env); err != nil {
+			log.Print(err)
+			metrics.Default().ParseError(receiverName)
+			continue
+		}
+
+		if err := applyEnvelope(env); err != nil {
+			log.Print(err)
+			metrics.Default().ParseError(receiverName)
+			continue
+		}
+
+		metrics.Default().FrameParsed(receiverName)
+
+	}
+
+}
+
+func stopListening() {
+	serverEnabled = false
+	if subSocket != nil {
+		subSocket.Close()
+	}
+}
+
+// New creates a new MotionReceiver that SUBs to a remote ZeroMQ PUB socket
+// carrying JSON-encoded obj.VRM deltas, as an alternative to TCP-framed
+// device protocols like facemotion3d's.
+func New(appConfig *config.App) *receivers.MotionReceiver {
+
+	zmqReceiver = receivers.New(appConfig, listenZMQ, stopListening)
+	return zmqReceiver
+
+}
@@ -18,11 +18,14 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package router
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -30,6 +33,11 @@ import (
 	"github.com/thatpix3l/fntwo/config"
 	"github.com/thatpix3l/fntwo/frontend"
 	"github.com/thatpix3l/fntwo/obj"
+	"github.com/thatpix3l/fntwo/pkg/auth"
+	"github.com/thatpix3l/fntwo/pkg/grpcapi"
+	"github.com/thatpix3l/fntwo/pkg/metrics"
+	"github.com/thatpix3l/fntwo/pkg/receivers/replay"
+	"github.com/thatpix3l/fntwo/pkg/receivers/zmq"
 	"github.com/thatpix3l/fntwo/pool"
 	"github.com/thatpix3l/fntwo/receivers"
 )
@@ -38,6 +46,12 @@ var (
 	activeReceiver *receivers.MotionReceiver
 )
 
+// secondsToDuration converts a float number of seconds, as taken from a
+// query parameter, to a time.Duration.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
 // Helper function to upgrade an HTTP connection to WebSockets
 func wsUpgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
 	var upgrader = websocket.Upgrader{
@@ -84,6 +98,20 @@ func saveScene(scene *config.Scene, sceneFilePath string) error {
 
 func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*receivers.MotionReceiver) *mux.Router {
 
+	// Set up the process-wide metrics Reporter before starting any
+	// receiver below. Receivers call into metrics.Default() from their own
+	// goroutine, so Init must run first or that call can race the package
+	// level Reporter's initialization. When metrics are disabled, every
+	// call on it is a no-op.
+	metricsReporter := metrics.Init(appCfg.MetricsEnabled)
+
+	// Register the ZeroMQ receiver alongside whatever receiverMap already
+	// holds, if --zmq-sub is set. zmq.New was otherwise never called,
+	// leaving that flag dead.
+	if appCfg.ZMQSubAddr != "" {
+		receiverMap["zmq"] = zmq.New(appCfg)
+	}
+
 	// Use the first motion receiver in map
 	for name, receiver := range receiverMap {
 		log.Printf("The active receiver is %s", name)
@@ -92,9 +120,42 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 		break
 	}
 
+	// Mirror the active receiver's VRM state onto a ZeroMQ PUB socket, if
+	// --zmq-pub is set. NewPublisher/Start were otherwise never called,
+	// leaving --zmq-pub and --zmq-rate dead. The publisher stays bound to
+	// the receiver active here; a later change of the active receiver is
+	// not followed.
+	if appCfg.ZMQPubAddr != "" {
+		publisher, err := zmq.NewPublisher(activeReceiver, appCfg.ZMQPubAddr, appCfg.ZMQPubRate)
+		if err != nil {
+			log.Println(err)
+		} else {
+			go publisher.Start()
+		}
+	}
+
+	// Set up OIDC auth for the mutating admin routes below. With no issuer
+	// configured, authVerifier.RequireIDToken is a no-op.
+	authVerifier, err := auth.New(context.Background(), appCfg.OIDCIssuer, appCfg.OIDCClientID, appCfg.OIDCRequiredClaim)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Router for API and web frontend
 	router := mux.NewRouter()
 
+	// Serve Prometheus metrics on their own address rather than this
+	// router, so scraping them doesn't require exposing the rest of the
+	// API. Serve is a no-op-safe Reporter even when metrics are disabled,
+	// but there's no reason to bind a listener nobody will ever scrape.
+	if appCfg.MetricsEnabled {
+		go func() {
+			if err := metrics.Serve(metricsReporter, appCfg.MetricsListenAddr); err != nil {
+				log.Println(err)
+			}
+		}()
+	}
+
 	// Route for relaying the internal state of the camera to all clients
 	cameraPool := pool.New()
 	router.HandleFunc("/client/camera", func(w http.ResponseWriter, r *http.Request) {
@@ -107,6 +168,9 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 			log.Println(err)
 		}
 
+		metricsReporter.ClientConnected("/client/camera")
+		defer metricsReporter.ClientDisconnected("/client/camera")
+
 		// Add a new camera client
 		cameraClient := cameraPool.Create(func(relayedData interface{}) {
 
@@ -157,8 +221,13 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 			return
 		}
 
+		metricsReporter.ClientConnected("/client/model")
+		defer metricsReporter.ClientDisconnected("/client/model")
+
 		for {
 
+			tickStart := time.Now()
+
 			// Process and send the VRM data to WebSocket
 			activeReceiver.VRM.Read(func(vrm *obj.VRM) {
 
@@ -172,6 +241,8 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 			// Wait for whatever how long, per second. By default, 1/60 of a second
 			time.Sleep(time.Duration(1e9 / appCfg.ModelUpdateFrequency))
 
+			metricsReporter.ObserveModelTick(time.Since(tickStart))
+
 		}
 
 	})
@@ -191,7 +262,7 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 	}).Methods("GET", "OPTIONS")
 
 	// Route for setting the default VRM model
-	router.HandleFunc("/api/model", func(w http.ResponseWriter, r *http.Request) {
+	router.Handle("/api/model", authVerifier.RequireIDToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		log.Println("Received request to set default VRM file")
 
@@ -210,10 +281,10 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 			return
 		}
 
-	}).Methods("PUT", "OPTIONS")
+	}))).Methods("PUT", "OPTIONS")
 
 	// Route for saving the internal state of the scene config
-	router.HandleFunc("/api/config/scene", func(w http.ResponseWriter, r *http.Request) {
+	router.Handle("/api/config/scene", authVerifier.RequireIDToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		log.Println("Received request to save current scene")
 
@@ -225,7 +296,7 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 			return
 		}
 
-	}).Methods("PUT", "OPTIONS")
+	}))).Methods("PUT", "OPTIONS")
 
 	// Route for retrieving the initial config for the server
 	router.HandleFunc("/api/config/app", func(w http.ResponseWriter, r *http.Request) {
@@ -249,7 +320,7 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 	}).Methods("GET", "OPTIONS")
 
 	// Route for changing the active MotionReceiver source used
-	router.HandleFunc("/api/receiver/change", func(w http.ResponseWriter, r *http.Request) {
+	router.Handle("/api/receiver/change", authVerifier.RequireIDToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		log.Println("Received request to change the MotionReceiver source for model")
 
@@ -271,7 +342,79 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 		activeReceiver = receiverMap[suggestedReceiver]
 		log.Printf("Successfully changed the active receiver to %s", suggestedReceiver)
 
-	}).Methods("POST", "OPTIONS")
+	}))).Methods("POST", "OPTIONS")
+
+	// Route for starting a recording of the active receiver's VRM state
+	router.Handle("/api/replay/record/start", authVerifier.RequireIDToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		path := r.URL.Query().Get("path")
+		if path == "" {
+			http.Error(w, "missing \"path\" query parameter", http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("Received request to start recording to %q", path)
+
+		if err := replay.StartRecording(activeReceiver, path); err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+	}))).Methods("POST", "OPTIONS")
+
+	// Route for stopping the in-progress recording, if any
+	router.Handle("/api/replay/record/stop", authVerifier.RequireIDToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		log.Println("Received request to stop recording")
+
+		if err := replay.StopRecording(); err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+	}))).Methods("POST", "OPTIONS")
+
+	// Route for playing back a recording into the replay receiver
+	router.Handle("/api/replay/play", authVerifier.RequireIDToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		query := r.URL.Query()
+
+		path := query.Get("path")
+		if path == "" {
+			http.Error(w, "missing \"path\" query parameter", http.StatusBadRequest)
+			return
+		}
+
+		speed, err := strconv.ParseFloat(query.Get("speed"), 64)
+		if err != nil {
+			speed = 1
+		}
+
+		loopStart, _ := strconv.ParseFloat(query.Get("loop_start"), 64)
+		loopEnd, _ := strconv.ParseFloat(query.Get("loop_end"), 64)
+
+		log.Printf("Received request to play back recording %q at %.2fx speed", path, speed)
+
+		replay.Play(path, speed, secondsToDuration(loopStart), secondsToDuration(loopEnd))
+
+	}))).Methods("POST", "OPTIONS")
+
+	// Route for seeking the in-progress playback
+	router.Handle("/api/replay/seek", authVerifier.RequireIDToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		to, err := strconv.ParseFloat(r.URL.Query().Get("to"), 64)
+		if err != nil {
+			http.Error(w, "invalid or missing \"to\" query parameter", http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("Received request to seek replay to %.2fs", to)
+
+		replay.Seek(secondsToDuration(to))
+
+	}))).Methods("POST", "OPTIONS")
 
 	// All other requests are sent to the embedded web frontend
 	frontendRoot, err := frontend.FS()
@@ -280,6 +423,19 @@ func New(appCfg *config.App, sceneCfg *config.Scene, receiverMap map[string]*rec
 	}
 	router.PathPrefix("/").Handler(http.FileServer(http.FS(frontendRoot)))
 
+	// Serve the gRPC API on its own port alongside this router, sharing
+	// the same camera pool and active receiver as the WebSocket routes.
+	grpcServer := grpcapi.New(appCfg, cameraPool, receiverMap,
+		func() *receivers.MotionReceiver { return activeReceiver },
+		func(r *receivers.MotionReceiver) { activeReceiver = r },
+	)
+	go func() {
+		grpcAddr := fmt.Sprintf("%s:%d", appCfg.GRPCListenIP, appCfg.GRPCListenPort)
+		if err := grpcapi.Serve(grpcServer, grpcAddr); err != nil {
+			log.Println(err)
+		}
+	}()
+
 	return router
 
 }